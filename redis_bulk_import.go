@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis"
+)
+
+type BulkImportOptions struct {
+	BatchSize int  // items (JSON keys or script lines) per pipeline flush; defaults to 500
+	DryRun    bool // parse and report progress, but issue no writes
+	Merge     bool // true: write into existing keys; false: DEL each JSON-map key first (script lines are replayed as they are, with no DEL)
+}
+
+type BulkImportProgress struct {
+	Processed int
+	Total     int
+	Errors    []string
+}
+
+// bulkImportItem is either one key from a JSON export (Key/Value set) or one
+// raw command line from a Redis-format export (Command set).
+type bulkImportItem struct {
+	Key     string
+	Value   interface{}
+	Command string
+}
+
+// BulkImport accepts either the JSON map exportKeysInJSONFormat produces or
+// a newline-delimited Redis-command script from exportKeysInRedisFormat, and
+// writes it in opts.BatchSize batches, reporting progress on the returned
+// channel until it's closed.
+func BulkImport(server RedisServer, payload string, opts BulkImportOptions) <-chan BulkImportProgress {
+	progress := make(chan BulkImportProgress)
+
+	go func() {
+		defer close(progress)
+
+		if opts.BatchSize <= 0 {
+			opts.BatchSize = 500
+		}
+
+		items, err := parseBulkImportPayload(payload)
+		if err != nil {
+			progress <- BulkImportProgress{Errors: []string{err.Error()}}
+			return
+		}
+
+		client := newRedisClient(server)
+		defer client.Close()
+
+		total := len(items)
+		var errs []string
+
+		for start := 0; start < total; start += opts.BatchSize {
+			end := start + opts.BatchSize
+			if end > total {
+				end = total
+			}
+			batch := items[start:end]
+
+			if !opts.DryRun {
+				if err := flushBulkImportBatch(client, batch, opts.Merge); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+
+			progress <- BulkImportProgress{Processed: end, Total: total, Errors: errs}
+		}
+	}()
+
+	return progress
+}
+
+// parseBulkImportPayload tries the JSON export shape first and falls back
+// to treating payload as a newline-delimited Redis-command script.
+func parseBulkImportPayload(payload string) ([]bulkImportItem, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(payload), &raw); err == nil {
+		items := make([]bulkImportItem, 0, len(raw))
+		for key, val := range raw {
+			items = append(items, bulkImportItem{Key: key, Value: val})
+		}
+		return items, nil
+	}
+
+	items := make([]bulkImportItem, 0)
+	for _, line := range strings.Split(payload, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		items = append(items, bulkImportItem{Command: line})
+	}
+	if len(items) == 0 {
+		return nil, errors.New("bulk import: payload is neither a JSON key map nor a command script")
+	}
+	return items, nil
+}
+
+// flushBulkImportBatch pipelines one batch of items through a single
+// TxPipeline round trip.
+func flushBulkImportBatch(client redis.UniversalClient, batch []bulkImportItem, merge bool) error {
+	pipe := client.TxPipeline()
+
+	for _, item := range batch {
+		if item.Command != "" {
+			args := splitRedisCommandLine(item.Command)
+			if len(args) == 0 {
+				continue
+			}
+			pipe.Do(args...)
+			continue
+		}
+
+		if err := bulkImportValue(pipe, item.Key, item.Value, merge); err != nil {
+			return err
+		}
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+// bulkImportValue writes one key from a JSON-export value, inferring the
+// Redis type from the Go type JSON decoded into: a string stays a string, a
+// map of maps is a stream (id -> fields), a map of numbers is a zset
+// (member -> score), any other map is a hash, and an array is replayed as a
+// list via RPush.
+func bulkImportValue(pipe redis.Pipeliner, key string, value interface{}, merge bool) error {
+	if !merge {
+		pipe.Del(key)
+	}
+
+	switch v := value.(type) {
+	case string:
+		pipe.Set(key, v, 0)
+	case map[string]interface{}:
+		switch {
+		case isStreamExport(v):
+			for id, fields := range v {
+				fieldMap, _ := fields.(map[string]interface{})
+				pipe.XAdd(&redis.XAddArgs{Stream: key, ID: id, Values: fieldMap})
+			}
+		case isZSetExport(v):
+			for member, score := range v {
+				pipe.ZAdd(key, redis.Z{Member: member, Score: score.(float64)})
+			}
+		default:
+			pipe.HMSet(key, v)
+		}
+	case []interface{}:
+		pipe.RPush(key, v...)
+	default:
+		return fmt.Errorf("bulk import: unsupported value type for key %q", key)
+	}
+	return nil
+}
+
+// isStreamExport reports whether v matches the {id: {field: value}} shape
+// exportKeysInJSONFormat emits for streams.
+func isStreamExport(v map[string]interface{}) bool {
+	if len(v) == 0 {
+		return false
+	}
+	for _, val := range v {
+		if _, ok := val.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// isZSetExport reports whether v matches the {member: score} shape
+// exportKeysInJSONFormat emits for zsets.
+func isZSetExport(v map[string]interface{}) bool {
+	if len(v) == 0 {
+		return false
+	}
+	for _, val := range v {
+		if _, ok := val.(float64); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// splitRedisCommandLine tokenizes one line of a Redis-format export,
+// unquoting the strconv.Quote-style tokens exportKeysInRedisFormat produces
+// into raw arguments for Do.
+func splitRedisCommandLine(line string) []interface{} {
+	var args []interface{}
+	var b strings.Builder
+	inQuotes := false
+	escaped := false
+
+	flush := func() {
+		if b.Len() == 0 {
+			return
+		}
+		tok := b.String()
+		if unquoted, err := strconv.Unquote(tok); err == nil {
+			args = append(args, unquoted)
+		} else {
+			args = append(args, tok)
+		}
+		b.Reset()
+	}
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case escaped:
+			b.WriteByte(c)
+			escaped = false
+		case c == '\\' && inQuotes:
+			b.WriteByte(c)
+			escaped = true
+		case c == '"':
+			b.WriteByte(c)
+			if inQuotes {
+				flush()
+			}
+			inQuotes = !inQuotes
+		case c == ' ' && !inQuotes:
+			flush()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+
+	return args
+}
@@ -2,23 +2,49 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/go-redis/redis"
 	"log"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 )
 
-func newRedisClient(server RedisServer) *redis.Client {
-	return redis.NewClient(&redis.Options{
-		Addr:     server.Addr,
-		Password: server.Password,  // no password set
-		DB:       server.DefaultDb, // use default DB
+func newRedisClient(server RedisServer) redis.UniversalClient {
+	if len(server.ClusterAddrs) > 0 {
+		// NewUniversalClient only builds a cluster client for two or more
+		// addresses, so a single cluster seed address is handled here.
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:     server.ClusterAddrs,
+			Password:  server.Password,
+			TLSConfig: server.TLSConfig,
+		})
+	}
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:      universalAddrs(server),
+		DB:         server.DefaultDb,
+		Password:   server.Password,
+		MasterName: server.MasterName,
+		TLSConfig:  server.TLSConfig,
 	})
 }
 
+func universalAddrs(server RedisServer) []string {
+	if len(server.ClusterAddrs) > 0 {
+		return server.ClusterAddrs
+	}
+	if server.MasterName != "" && len(server.SentinelAddrs) > 0 {
+		return server.SentinelAddrs
+	}
+	return []string{server.Addr}
+}
+
 func redisInfo(server RedisServer) string {
 	client := newRedisClient(server)
 	defer client.Close()
@@ -28,6 +54,12 @@ func redisInfo(server RedisServer) string {
 }
 
 func configGetDatabases(server RedisServer) int {
+	if len(server.ClusterAddrs) > 0 {
+		// Redis Cluster only ever exposes DB 0, and CONFIG GET on a cluster
+		// client would just answer from an arbitrary node, so skip it.
+		return 1
+	}
+
 	client := newRedisClient(server)
 	defer client.Close()
 
@@ -52,12 +84,14 @@ func exportRedisKeys(server RedisServer, keys, exportType string) interface{} {
 		return exportKeysInRedisFormat(client, exportKeys)
 	} else if exportType == "JSON" {
 		return exportKeysInJSONFormat(client, exportKeys)
+	} else if exportType == "CSV" {
+		return exportKeysInCSVFormat(client, exportKeys)
 	} else {
 		return ""
 	}
 }
 
-func exportKeysInJSONFormat(client *redis.Client, exportKeys []string) map[string]interface{} {
+func exportKeysInJSONFormat(client redis.UniversalClient, exportKeys []string) map[string]interface{} {
 	var result = make(map[string]interface{})
 	for _, key := range exportKeys {
 		keyType, _ := client.Type(key).Result()
@@ -81,15 +115,26 @@ func exportKeysInJSONFormat(client *redis.Client, exportKeys []string) map[strin
 			members, _ := client.SMembers(key).Result()
 			result[key] = members
 		case "zset":
-			members, _ := client.ZRange(key, 0, -1).Result()
-			result[key] = members
+			members, _ := client.ZRangeWithScores(key, 0, -1).Result()
+			scores := make(map[string]float64, len(members))
+			for _, member := range members {
+				scores[fmt.Sprintf("%v", member.Member)] = member.Score
+			}
+			result[key] = scores
+		case "stream":
+			entries, _ := client.XRange(key, "-", "+").Result()
+			stream := make(map[string]map[string]string, len(entries))
+			for _, entry := range toStreamEntries(entries) {
+				stream[entry.ID] = entry.Fields
+			}
+			result[key] = stream
 		}
 	}
 
 	return result
 }
 
-func exportKeysInRedisFormat(client *redis.Client, exportKeys []string) []string {
+func exportKeysInRedisFormat(client redis.UniversalClient, exportKeys []string) []string {
 	result := make([]string, 0)
 	for _, key := range exportKeys {
 		keyType, _ := client.Type(key).Result()
@@ -112,7 +157,7 @@ func exportKeysInRedisFormat(client *redis.Client, exportKeys []string) []string
 		case "set":
 			members, _ := client.SMembers(key).Result()
 			for _, member := range members {
-				result = append(result, `SADD `+strconv.Quote(key)+` `+strconv.Quote(member)+`\r\n`)
+				result = append(result, `SADD `+strconv.Quote(key)+` `+strconv.Quote(member))
 			}
 		case "zset":
 			members, _ := client.ZRange(key, 0, -1).Result()
@@ -120,12 +165,140 @@ func exportKeysInRedisFormat(client *redis.Client, exportKeys []string) []string
 				score, _ := client.ZScore(key, member).Result()
 				result = append(result, `ZADD `+strconv.Quote(key)+` `+strconv.FormatFloat(score, 'f', -1, 64)+` `+strconv.Quote(member))
 			}
+		case "stream":
+			entries, _ := client.XRange(key, "-", "+").Result()
+			for _, entry := range toStreamEntries(entries) {
+				cmd := `XADD ` + strconv.Quote(key) + ` ` + strconv.Quote(entry.ID)
+				for field, value := range entry.Fields {
+					cmd += ` ` + strconv.Quote(field) + ` ` + strconv.Quote(value)
+				}
+				result = append(result, cmd)
+			}
 		}
 	}
 
 	return result
 }
 
+// exportKeysInCSVFormat buckets keys by type into one CSV file per type,
+// running values through convertString first so binary-unsafe payloads
+// don't break the CSV quoting.
+func exportKeysInCSVFormat(client redis.UniversalClient, exportKeys []string) map[string]string {
+	buffers := make(map[string]*bytes.Buffer)
+	writers := make(map[string]*csv.Writer)
+
+	writerFor := func(keyType string) *csv.Writer {
+		if w, ok := writers[keyType]; ok {
+			return w
+		}
+		buf := &bytes.Buffer{}
+		w := csv.NewWriter(buf)
+		buffers[keyType] = buf
+		writers[keyType] = w
+		return w
+	}
+
+	for _, key := range exportKeys {
+		keyType, _ := client.Type(key).Result()
+		switch keyType {
+		case "string":
+			val, _ := client.Get(key).Result()
+			ttl, _ := client.TTL(key).Result()
+			writerFor(keyType).Write([]string{key, ttl.String(), convertString(val)})
+		case "hash":
+			vals, _ := client.HGetAll(key).Result()
+			w := writerFor(keyType)
+			for field, val := range vals {
+				w.Write([]string{key, convertString(field), convertString(val)})
+			}
+		case "list":
+			length, _ := client.LLen(key).Result()
+			w := writerFor(keyType)
+			for i := int64(0); i < length; i++ {
+				val, _ := client.LIndex(key, i).Result()
+				w.Write([]string{key, strconv.FormatInt(i, 10), convertString(val)})
+			}
+		case "set":
+			members, _ := client.SMembers(key).Result()
+			w := writerFor(keyType)
+			for _, member := range members {
+				w.Write([]string{key, convertString(member)})
+			}
+		case "zset":
+			members, _ := client.ZRangeWithScores(key, 0, -1).Result()
+			w := writerFor(keyType)
+			for _, member := range members {
+				w.Write([]string{key, convertString(fmt.Sprintf("%v", member.Member)), strconv.FormatFloat(member.Score, 'f', -1, 64)})
+			}
+		}
+	}
+
+	result := make(map[string]string, len(buffers))
+	for keyType, buf := range buffers {
+		writers[keyType].Flush()
+		result[keyType] = buf.String()
+	}
+	return result
+}
+
+func importCSV(server RedisServer, keyType, csvData string) string {
+	client := newRedisClient(server)
+	defer client.Close()
+
+	records, err := csv.NewReader(strings.NewReader(csvData)).ReadAll()
+	if err != nil {
+		return err.Error()
+	}
+
+	pipe := client.Pipeline()
+	for _, record := range records {
+		switch keyType {
+		case "string":
+			if len(record) < 3 {
+				continue
+			}
+			duration := time.Duration(-1)
+			if record[1] != "" && record[1] != "-1s" {
+				duration, err = time.ParseDuration(record[1])
+				if err != nil {
+					return err.Error()
+				}
+			}
+			pipe.Set(record[0], unconvertString(record[2]), duration)
+		case "hash":
+			if len(record) < 3 {
+				continue
+			}
+			pipe.HSet(record[0], unconvertString(record[1]), unconvertString(record[2]))
+		case "list":
+			if len(record) < 3 {
+				continue
+			}
+			pipe.RPush(record[0], unconvertString(record[2]))
+		case "set":
+			if len(record) < 2 {
+				continue
+			}
+			pipe.SAdd(record[0], unconvertString(record[1]))
+		case "zset":
+			if len(record) < 3 {
+				continue
+			}
+			score, err := strconv.ParseFloat(record[2], 64)
+			if err != nil {
+				return err.Error()
+			}
+			pipe.ZAdd(record[0], redis.Z{Member: unconvertString(record[1]), Score: score})
+		}
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		return err.Error()
+	}
+
+	return "OK"
+}
+
 func newKey(server RedisServer, keyType, key, ttl, val string) string {
 	client := newRedisClient(server)
 	defer client.Close()
@@ -189,6 +362,28 @@ func newKey(server RedisServer, keyType, key, ttl, val string) string {
 		if err == nil && duration > 0 {
 			client.Expire(key, duration)
 		}
+	case "stream":
+		var entries []StreamEntry
+		err = json.Unmarshal([]byte(val), &entries)
+		if err == nil {
+			for _, entry := range entries {
+				id := entry.ID
+				if id == "" {
+					id = "*"
+				}
+				values := make(map[string]interface{}, len(entry.Fields))
+				for field, value := range entry.Fields {
+					values[field] = value
+				}
+				_, err = client.XAdd(&redis.XAddArgs{Stream: key, ID: id, Values: values}).Result()
+				if err != nil {
+					break
+				}
+			}
+		}
+		if err == nil && duration > 0 {
+			client.Expire(key, duration)
+		}
 	}
 
 	if err != nil {
@@ -212,20 +407,31 @@ func deleteMultiKeys(server RedisServer, keys ...string) string {
 }
 
 type ContentResult struct {
-	Exists   bool
-	Content  interface{}
-	Ttl      string
-	Encoding string
-	Size     int64
-	Error    string
-	Format   string // JSON, NORMAL, UNKNOWN
-	Type     string
+	Exists       bool
+	Content      interface{}
+	Ttl          string
+	Encoding     string
+	Size         int64
+	Error        string
+	Format       string // NORMAL, UNKNOWN, or a decoder label such as JSON, GZIP+JSON, MSGPACK, PICKLE
+	Type         string
+	NextCursor   uint64 // next HSCAN/SSCAN/ZSCAN cursor for hash/set/zset, 0 when exhausted
+	NextOffset   int64  // next LRANGE offset for list, -1 when exhausted
+	NextStreamID string // next XRANGE start id for stream, "" when exhausted
 }
 
-func displayContent(server RedisServer, key string, maxContentCheck bool, raw bool) *ContentResult {
+const defaultPageSize = 100
+
+// displayContent renders a key's value. Scalar strings come back whole;
+// hash/set/zset/list/stream contents are paged from subCursor/offset/fromID.
+func displayContent(server RedisServer, key string, maxContentCheck bool, raw bool, subCursor uint64, offset int64, pageSize int64, fromID string) *ContentResult {
 	client := newRedisClient(server)
 	defer client.Close()
 
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
 	exists, _ := client.Exists(key).Result()
 	if exists == 0 {
 		return &ContentResult{
@@ -247,6 +453,9 @@ func displayContent(server RedisServer, key string, maxContentCheck bool, raw bo
 	var format string
 	var err error
 	var size int64
+	var nextCursor uint64
+	var nextStreamID string
+	nextOffset := int64(-1)
 
 	valType, _ := client.Type(key).Result()
 
@@ -263,18 +472,25 @@ func displayContent(server RedisServer, key string, maxContentCheck bool, raw bo
 			}
 		}
 	case "hash":
-		content, err = client.HGetAll(key).Result()
+		var vals map[string]string
+		vals, nextCursor, err = scanHash(client, key, subCursor, pageSize)
+		content = parseHashContent(vals)
 		size, _ = client.HLen(key).Result()
-		content = parseHashContent(content.(map[string]string))
 	case "list":
-		content, err = client.LRange(key, 0, -1).Result()
+		content, err = client.LRange(key, offset, offset+pageSize-1).Result()
 		size, _ = client.LLen(key).Result()
+		if offset+pageSize < size {
+			nextOffset = offset + pageSize
+		}
 	case "set":
-		content, err = client.SMembers(key).Result()
+		content, nextCursor, err = client.SScan(key, subCursor, "", pageSize).Result()
 		size, _ = client.SCard(key).Result()
 	case "zset":
-		content, err = client.ZRangeWithScores(key, 0, -1).Result()
+		content, nextCursor, err = scanZSet(client, key, subCursor, pageSize)
 		size, _ = client.ZCard(key).Result()
+	case "stream":
+		content, nextStreamID, err = scanStream(client, key, fromID, pageSize)
+		size, _ = client.XLen(key).Result()
 	default:
 		content = "unknown type " + valType
 	}
@@ -284,16 +500,88 @@ func displayContent(server RedisServer, key string, maxContentCheck bool, raw bo
 	}
 
 	return &ContentResult{
-		Exists:   true,
-		Content:  content,
-		Ttl:      ttl.String(),
-		Encoding: encoding,
-		Size:     size,
-		Error:    errorMessage,
-		Format:   format,
-		Type:     valType,
+		Exists:       true,
+		Content:      content,
+		Ttl:          ttl.String(),
+		Encoding:     encoding,
+		Size:         size,
+		Error:        errorMessage,
+		Format:       format,
+		Type:         valType,
+		NextCursor:   nextCursor,
+		NextOffset:   nextOffset,
+		NextStreamID: nextStreamID,
 	}
 }
+
+type StreamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+// scanStream pages a stream's entries via XRANGE starting at fromID (empty
+// means "from the start"), returning the next fromID in Redis's own
+// exclusive-range syntax ("(<id>") once there's another page, so the next
+// page starts just after the last entry returned.
+func scanStream(client redis.UniversalClient, key, fromID string, pageSize int64) ([]StreamEntry, string, error) {
+	start := fromID
+	if start == "" {
+		start = "-"
+	}
+
+	msgs, err := client.XRangeN(key, start, "+", pageSize+1).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextID string
+	if int64(len(msgs)) > pageSize {
+		msgs = msgs[:pageSize]
+		nextID = "(" + msgs[len(msgs)-1].ID
+	}
+
+	return toStreamEntries(msgs), nextID, nil
+}
+
+func toStreamEntries(msgs []redis.XMessage) []StreamEntry {
+	entries := make([]StreamEntry, len(msgs))
+	for i, msg := range msgs {
+		fields := make(map[string]string, len(msg.Values))
+		for field, value := range msg.Values {
+			fields[field] = fmt.Sprintf("%v", value)
+		}
+		entries[i] = StreamEntry{ID: msg.ID, Fields: fields}
+	}
+	return entries
+}
+
+func scanHash(client redis.UniversalClient, key string, cursor uint64, pageSize int64) (map[string]string, uint64, error) {
+	fields, next, err := client.HScan(key, cursor, "", pageSize).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	vals := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		vals[fields[i]] = fields[i+1]
+	}
+	return vals, next, nil
+}
+
+// scanZSet pairs up ZSCAN's flat member/score results into redis.Z values.
+func scanZSet(client redis.UniversalClient, key string, cursor uint64, pageSize int64) ([]redis.Z, uint64, error) {
+	raw, next, err := client.ZScan(key, cursor, "", pageSize).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	members := make([]redis.Z, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		score, _ := strconv.ParseFloat(raw[i+1], 64)
+		members = append(members, redis.Z{Member: raw[i], Score: score})
+	}
+	return members, next, nil
+}
 func parseHashContent(m map[string]string) map[string]string {
 	converted := make(map[string]string, len(m))
 	for k, v := range m {
@@ -314,6 +602,16 @@ func convertString(s string) string {
 	return quote[1 : len(quote)-1]
 }
 
+// unconvertString reverses convertString, unescaping the \xNN-style escapes
+// used for values that aren't printable UTF-8 text.
+func unconvertString(s string) string {
+	unquoted, err := strconv.Unquote(`"` + s + `"`)
+	if err != nil {
+		return s
+	}
+	return unquoted
+}
+
 var re = regexp.MustCompile(`\\x(..)`)
 
 func parseStringFormat(s string) (string, string) {
@@ -321,8 +619,8 @@ func parseStringFormat(s string) (string, string) {
 		return s, "UNKNOWN"
 	}
 
-	if isJSON(s) {
-		return jsonPrettyPrint(s), "JSON"
+	if decoded, label, ok := decodeWithChain([]byte(s)); ok {
+		return decoded, label
 	}
 
 	if isPrintable(s) {
@@ -363,45 +661,222 @@ type KeysResult struct {
 	Len  int64
 }
 
-func listKeys(server RedisServer, cursor uint64, matchPattern string, maxKeys int) ([]KeysResult, uint64, error) {
+type ScanKeysResult struct {
+	Keys       []KeysResult
+	NextCursor uint64
+}
+
+// globMetaChars are the characters SCAN's MATCH treats specially.
+const globMetaChars = `*?[]^`
+
+func looksLikeGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, globMetaChars)
+}
+
+// ScanKeys returns a single SCAN page for db starting at cursor, with the
+// per-key TYPE/LEN lookups pipelined. When matchAnywhere is set and pattern
+// isn't a native glob, the page is scanned with MATCH * and filtered
+// client-side for keys containing pattern. Fans out across Redis Cluster
+// master shards via scanClusterKeys when server.ClusterAddrs is set.
+func ScanKeys(server RedisServer, db int, cursor uint64, pattern string, pageSize int64, matchAnywhere bool) (*ScanKeysResult, error) {
+	server.DefaultDb = db
 	client := newRedisClient(server)
 	defer client.Close()
 
-	allKeys := make([]KeysResult, 0)
-	var keys []string
-	ncursor := cursor
-	var err error
+	if len(server.ClusterAddrs) > 0 {
+		return scanClusterKeys(client, cursor, pattern, pageSize, matchAnywhere)
+	}
 
-	for {
-		keys, ncursor, err = client.Scan(ncursor, matchPattern, 10).Result()
+	scanPattern := pattern
+	clientFilter := matchAnywhere && pattern != "" && !looksLikeGlob(pattern)
+	if clientFilter {
+		scanPattern = "*"
+	}
+
+	keys, ncursor, err := client.Scan(cursor, scanPattern, pageSize).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if clientFilter {
+		keys = filterKeysContaining(keys, pattern)
+	}
+
+	results, err := pipelineKeyTypesAndLengths(client, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanKeysResult{Keys: results, NextCursor: ncursor}, nil
+}
+
+func filterKeysContaining(keys []string, pattern string) []string {
+	filtered := keys[:0]
+	for _, key := range keys {
+		if strings.Contains(key, pattern) {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered
+}
+
+// clusterCursorShardBits is how many of a uint64 cursor's high bits encode
+// the shard index; the rest carries that shard's native SCAN cursor.
+const clusterCursorShardBits = 16
+
+func encodeClusterCursor(shardIndex int, shardCursor uint64) uint64 {
+	return uint64(shardIndex)<<(64-clusterCursorShardBits) | shardCursor
+}
+
+func decodeClusterCursor(cursor uint64) (shardIndex int, shardCursor uint64) {
+	shardIndex = int(cursor >> (64 - clusterCursorShardBits))
+	shardCursor = cursor &^ (uint64(0xFFFF) << (64 - clusterCursorShardBits))
+	return shardIndex, shardCursor
+}
+
+// scanClusterKeys pages a Redis Cluster one master shard at a time: cursor
+// packs a shard index and that shard's own SCAN cursor (see
+// encode/decodeClusterCursor), so repeated calls walk every shard in turn
+// and merge their keys instead of a bare Scan landing on one arbitrary node.
+func scanClusterKeys(client redis.UniversalClient, cursor uint64, pattern string, pageSize int64, matchAnywhere bool) (*ScanKeysResult, error) {
+	clusterClient, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return nil, errors.New("ScanKeys: ClusterAddrs configured but client is not a cluster client")
+	}
+
+	masters, err := clusterMasters(clusterClient)
+	if err != nil {
+		return nil, err
+	}
+
+	scanPattern := pattern
+	clientFilter := matchAnywhere && pattern != "" && !looksLikeGlob(pattern)
+	if clientFilter {
+		scanPattern = "*"
+	}
+
+	shardIndex, shardCursor := decodeClusterCursor(cursor)
+
+	for shardIndex < len(masters) {
+		keys, nextShardCursor, err := masters[shardIndex].Scan(shardCursor, scanPattern, pageSize).Result()
 		if err != nil {
-			return nil, ncursor, err
+			return nil, err
 		}
 
-		for _, key := range keys {
-			valType, err := client.Type(key).Result()
-			if err != nil {
-				return nil, ncursor, err
-			}
+		if clientFilter {
+			keys = filterKeysContaining(keys, pattern)
+		}
 
-			var conentLen int64
-			switch valType {
-			case "string":
-				conentLen, _ = client.StrLen(key).Result()
-			case "list":
-				conentLen, _ = client.LLen(key).Result()
-			case "hash":
-				conentLen, _ = client.HLen(key).Result()
-			case "set":
-				conentLen, _ = client.SCard(key).Result()
-			case "zset":
-				conentLen, _ = client.ZCard(key).Result()
-			default:
-				conentLen = -1
+		results, err := pipelineKeyTypesAndLengths(masters[shardIndex], keys)
+		if err != nil {
+			return nil, err
+		}
+
+		if nextShardCursor != 0 {
+			return &ScanKeysResult{Keys: results, NextCursor: encodeClusterCursor(shardIndex, nextShardCursor)}, nil
+		}
+
+		shardIndex++
+		shardCursor = 0
+
+		if len(results) > 0 || shardIndex >= len(masters) {
+			var next uint64
+			if shardIndex < len(masters) {
+				next = encodeClusterCursor(shardIndex, 0)
 			}
+			return &ScanKeysResult{Keys: results, NextCursor: next}, nil
+		}
+	}
+
+	return &ScanKeysResult{Keys: []KeysResult{}, NextCursor: 0}, nil
+}
+
+// clusterMasters lists the cluster's master shards in a stable address
+// order, so a shard index means the same shard across successive calls.
+func clusterMasters(clusterClient *redis.ClusterClient) ([]*redis.Client, error) {
+	var masters []*redis.Client
+	err := clusterClient.ForEachMaster(func(master *redis.Client) error {
+		masters = append(masters, master)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(masters, func(i, j int) bool {
+		return masters[i].Options().Addr < masters[j].Options().Addr
+	})
+	return masters, nil
+}
+
+// pipelineKeyTypesAndLengths batches the TYPE lookup and the type-specific
+// length lookup for a page of keys into two round trips total, rather than
+// the 2N serial calls the old listKeys made.
+func pipelineKeyTypesAndLengths(client redis.UniversalClient, keys []string) ([]KeysResult, error) {
+	if len(keys) == 0 {
+		return []KeysResult{}, nil
+	}
 
-			allKeys = append(allKeys, KeysResult{Key: key, Type: valType, Len: conentLen})
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	typePipe := client.Pipeline()
+	for i, key := range keys {
+		typeCmds[i] = typePipe.Type(key)
+	}
+	if _, err := typePipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	lenCmds := make([]*redis.IntCmd, len(keys))
+	lenPipe := client.Pipeline()
+	for i, key := range keys {
+		switch typeCmds[i].Val() {
+		case "string":
+			lenCmds[i] = lenPipe.StrLen(key)
+		case "list":
+			lenCmds[i] = lenPipe.LLen(key)
+		case "hash":
+			lenCmds[i] = lenPipe.HLen(key)
+		case "set":
+			lenCmds[i] = lenPipe.SCard(key)
+		case "zset":
+			lenCmds[i] = lenPipe.ZCard(key)
+		case "stream":
+			lenCmds[i] = lenPipe.XLen(key)
+		}
+	}
+	if _, err := lenPipe.Exec(); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	results := make([]KeysResult, len(keys))
+	for i, key := range keys {
+		contentLen := int64(-1)
+		if lenCmds[i] != nil {
+			contentLen = lenCmds[i].Val()
 		}
+		results[i] = KeysResult{Key: key, Type: typeCmds[i].Val(), Len: contentLen}
+	}
+
+	return results, nil
+}
+
+// listKeys walks SCAN pages via ScanKeys until it hits maxKeys or the scan
+// completes, preserving the old all-at-once signature for callers that
+// don't need cursor-based paging. ScanKeys itself fans out across Redis
+// Cluster master shards when server.ClusterAddrs is set, so this just keeps
+// paging until the (possibly composite) cursor comes back 0.
+func listKeys(server RedisServer, cursor uint64, matchPattern string, maxKeys int) ([]KeysResult, uint64, error) {
+	allKeys := make([]KeysResult, 0)
+	ncursor := cursor
+
+	for {
+		page, err := ScanKeys(server, server.DefaultDb, ncursor, matchPattern, 10, false)
+		if err != nil {
+			return nil, ncursor, err
+		}
+
+		allKeys = append(allKeys, page.Keys...)
+		ncursor = page.NextCursor
 
 		if ncursor == 0 || (maxKeys > 0 && len(allKeys) >= maxKeys) {
 			break
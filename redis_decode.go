@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"regexp"
+
+	ogorek "github.com/kisielk/og-rek"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Decoder is one step in the format-detection chain. Detect is a cheap
+// sniff (usually a magic-byte check); Decode does the real work.
+type Decoder interface {
+	Detect(b []byte) bool
+	Decode(b []byte) (interface{}, string, error)
+}
+
+var decoders []Decoder
+
+// RegisterDecoder adds d to the chain, tried in registration order.
+func RegisterDecoder(d Decoder) {
+	decoders = append(decoders, d)
+}
+
+func init() {
+	RegisterDecoder(gzipDecoder{})
+	RegisterDecoder(zstdDecoder{})
+	RegisterDecoder(pickleDecoder{})
+	RegisterDecoder(msgpackDecoder{})
+	RegisterDecoder(protobufDecoder{})
+	RegisterDecoder(base64JSONDecoder{})
+	RegisterDecoder(jsonDecoder{})
+}
+
+// decodeWithChain tries each registered decoder in order, short-circuiting
+// on the first successful decode. A decoder that produces raw bytes
+// (gzip/zstd) re-enters the chain, so a gzipped JSON blob comes back
+// labelled "GZIP+JSON" instead of just "GZIP".
+func decodeWithChain(b []byte) (string, string, bool) {
+	for _, d := range decoders {
+		if !d.Detect(b) {
+			continue
+		}
+
+		decoded, label, err := d.Decode(b)
+		if err != nil {
+			continue
+		}
+
+		if raw, ok := decoded.([]byte); ok {
+			if inner, innerLabel, ok := decodeWithChain(raw); ok {
+				return inner, label + "+" + innerLabel, true
+			}
+			return string(raw), label, true
+		}
+
+		if s, ok := decoded.(string); ok {
+			return s, label, true
+		}
+
+		pretty, err := json.MarshalIndent(decoded, "", "\t")
+		if err != nil {
+			continue
+		}
+		return string(pretty), label, true
+	}
+
+	return "", "", false
+}
+
+// jsonDecoder is the plain-JSON detection parseStringFormat always had,
+// last in the chain.
+type jsonDecoder struct{}
+
+func (jsonDecoder) Detect(b []byte) bool {
+	return len(b) > 0 && (b[0] == '{' || b[0] == '[')
+}
+
+func (jsonDecoder) Decode(b []byte) (interface{}, string, error) {
+	if !isJSON(string(b)) {
+		return nil, "", errNotDecodable
+	}
+	return jsonPrettyPrint(string(b)), "JSON", nil
+}
+
+type gzipDecoder struct{}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+func (gzipDecoder) Detect(b []byte) bool {
+	return bytes.HasPrefix(b, gzipMagic)
+}
+
+func (gzipDecoder) Decode(b []byte) (interface{}, string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.Close()
+
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, "GZIP", nil
+}
+
+type zstdDecoder struct{}
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+func (zstdDecoder) Detect(b []byte) bool {
+	return bytes.HasPrefix(b, zstdMagic)
+}
+
+func (zstdDecoder) Decode(b []byte) (interface{}, string, error) {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer dec.Close()
+
+	raw, err := dec.DecodeAll(b, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, "ZSTD", nil
+}
+
+// msgpackDecoder has no magic number, so Detect checks the first byte
+// against MessagePack's type-prefix ranges before attempting a decode.
+type msgpackDecoder struct{}
+
+func (msgpackDecoder) Detect(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	lead := b[0]
+	switch {
+	case lead == 0x80 && len(b) >= 2 && b[1] >= 2 && b[1] <= 5:
+		return false // looks like a pickle protocol 2-5 header, not a 0-entry fixmap
+	case lead >= 0x80 && lead <= 0x8f: // fixmap
+	case lead >= 0x90 && lead <= 0x9f: // fixarray
+	case lead >= 0xa0 && lead <= 0xbf: // fixstr
+	case lead >= 0xc4 && lead <= 0xdf: // bin/ext/array16.../map32
+	default:
+		return false
+	}
+	return true
+}
+
+func (msgpackDecoder) Decode(b []byte) (interface{}, string, error) {
+	var v interface{}
+	if err := msgpack.Unmarshal(b, &v); err != nil {
+		return nil, "", err
+	}
+	return v, "MSGPACK", nil
+}
+
+// protobufDecoder only activates once a descriptor set has been registered
+// via RegisterProtobufDescriptor; protobuf has no self-describing bytes.
+type protobufDecoder struct{}
+
+var protobufDescriptors []*protobufDescriptorSet
+
+// protobufDescriptorSet is a named set of message decoders protobufDecoder
+// tries in order until one unmarshals cleanly.
+type protobufDescriptorSet struct {
+	Name     string
+	Messages []ProtoMessageDecoder
+}
+
+// ProtoMessageDecoder unmarshals b as one specific protobuf message type.
+type ProtoMessageDecoder func(b []byte) (interface{}, error)
+
+// RegisterProtobufDescriptor registers message types for protobufDecoder to try.
+func RegisterProtobufDescriptor(name string, messages ...ProtoMessageDecoder) {
+	protobufDescriptors = append(protobufDescriptors, &protobufDescriptorSet{Name: name, Messages: messages})
+}
+
+func (protobufDecoder) Detect(b []byte) bool {
+	return len(b) > 0 && len(protobufDescriptors) > 0
+}
+
+func (protobufDecoder) Decode(b []byte) (interface{}, string, error) {
+	for _, set := range protobufDescriptors {
+		for _, decode := range set.Messages {
+			if v, err := decode(b); err == nil {
+				return v, "PROTOBUF", nil
+			}
+		}
+	}
+	return nil, "", errNotDecodable
+}
+
+// pickleDecoder detects Python pickle protocol 2+, which always opens with
+// the PROTO opcode (0x80) followed by the protocol version byte.
+type pickleDecoder struct{}
+
+func (pickleDecoder) Detect(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0x80 && b[1] >= 2 && b[1] <= 5
+}
+
+func (pickleDecoder) Decode(b []byte) (interface{}, string, error) {
+	v, err := ogorek.NewDecoder(bytes.NewReader(b)).Decode()
+	if err != nil {
+		return nil, "", err
+	}
+	return v, "PICKLE", nil
+}
+
+// base64JSONDecoder only claims a value when it's both valid base64 and
+// decodes to JSON, so it doesn't swallow arbitrary base64-looking text.
+type base64JSONDecoder struct{}
+
+var base64Pattern = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+
+func (base64JSONDecoder) Detect(b []byte) bool {
+	return len(b) > 0 && len(b)%4 == 0 && base64Pattern.Match(b)
+}
+
+func (base64JSONDecoder) Decode(b []byte) (interface{}, string, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(b))
+	if err != nil {
+		return nil, "", err
+	}
+	if !isJSON(string(raw)) {
+		return nil, "", errNotDecodable
+	}
+	return jsonPrettyPrint(string(raw)), "BASE64+JSON", nil
+}
+
+var errNotDecodable = errors.New("value does not match this format")
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+// protocol-2 pickle of the integer 5: PROTO 2, BININT1 5, STOP.
+var picklePayload = []byte{0x80, 0x02, 0x4b, 0x05, 0x2e}
+
+func TestDecodeWithChainPrefersPickleOverMsgpack(t *testing.T) {
+	if (msgpackDecoder{}).Detect(picklePayload) {
+		t.Fatal("msgpackDecoder.Detect should not claim a pickle protocol 2-5 payload")
+	}
+
+	_, label, ok := decodeWithChain(picklePayload)
+	if !ok {
+		t.Fatal("decodeWithChain should decode a pickle payload")
+	}
+	if label != "PICKLE" {
+		t.Fatalf("label = %q, want PICKLE", label)
+	}
+}
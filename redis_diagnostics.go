@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+type SlowLogEntry struct {
+	Timestamp string
+	Client    string
+	Addr      string
+	Cmd       string
+	Cost      string
+}
+
+// SlowLog returns the last count entries from SLOWLOG GET, newest first.
+// go-redis v6 has no typed SlowLogGet, so this issues the raw command and
+// parses its array reply by hand.
+func SlowLog(server RedisServer, count int64) []SlowLogEntry {
+	client := newRedisClient(server)
+	defer client.Close()
+
+	raw, err := universalDo(client, "SLOWLOG", "GET", count)
+	if err != nil {
+		log.Println("slowlog get error: ", err.Error())
+		return nil
+	}
+
+	return parseSlowLog(raw)
+}
+
+// universalDo issues a raw command against client. Do isn't part of the
+// Cmdable interface redis.UniversalClient embeds in this go-redis version,
+// so it's only reachable by type-asserting down to the concrete client
+// NewUniversalClient actually returned.
+func universalDo(client redis.UniversalClient, args ...interface{}) (interface{}, error) {
+	switch c := client.(type) {
+	case *redis.Client:
+		return c.Do(args...).Result()
+	case *redis.ClusterClient:
+		return c.Do(args...).Result()
+	default:
+		return nil, fmt.Errorf("universalDo: unsupported client type %T", client)
+	}
+}
+
+// parseSlowLog decodes SLOWLOG GET's reply: each entry is itself an array
+// of [id, timestamp, duration_micros, args, client_addr, client_name] (the
+// last two fields only present on Redis 4+).
+func parseSlowLog(raw interface{}) []SlowLogEntry {
+	rows, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	entries := make([]SlowLogEntry, 0, len(rows))
+	for _, row := range rows {
+		fields, ok := row.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+
+		var entry SlowLogEntry
+		if ts, ok := fields[1].(int64); ok {
+			entry.Timestamp = time.Unix(ts, 0).String()
+		}
+		if micros, ok := fields[2].(int64); ok {
+			entry.Cost = (time.Duration(micros) * time.Microsecond).String()
+		}
+		if args, ok := fields[3].([]interface{}); ok {
+			parts := make([]string, 0, len(args))
+			for _, a := range args {
+				if s, ok := a.(string); ok {
+					parts = append(parts, s)
+				}
+			}
+			entry.Cmd = strings.Join(parts, " ")
+		}
+		if len(fields) >= 5 {
+			if addr, ok := fields[4].(string); ok {
+				entry.Addr = addr
+			}
+		}
+		if len(fields) >= 6 {
+			if name, ok := fields[5].(string); ok {
+				entry.Client = name
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+type ClientInfo struct {
+	Addr string
+	Name string
+	DB   int
+	Cmd  string
+	Age  int64
+	Idle int64
+}
+
+// ClientList returns the connections reported by CLIENT LIST, parsed out of
+// its raw "key=value ..." line format.
+func ClientList(server RedisServer) []ClientInfo {
+	client := newRedisClient(server)
+	defer client.Close()
+
+	raw, err := client.ClientList().Result()
+	if err != nil {
+		log.Println("client list error: ", err.Error())
+		return nil
+	}
+
+	return parseClientList(raw)
+}
+
+func parseClientList(raw string) []ClientInfo {
+	lines := strings.Split(strings.TrimSpace(raw), "\n")
+	clients := make([]ClientInfo, 0, len(lines))
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		fields := make(map[string]string)
+		for _, pair := range strings.Fields(line) {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) == 2 {
+				fields[kv[0]] = kv[1]
+			}
+		}
+
+		db, _ := strconv.Atoi(fields["db"])
+		age, _ := strconv.ParseInt(fields["age"], 10, 64)
+		idle, _ := strconv.ParseInt(fields["idle"], 10, 64)
+
+		clients = append(clients, ClientInfo{
+			Addr: fields["addr"],
+			Name: fields["name"],
+			DB:   db,
+			Cmd:  fields["cmd"],
+			Age:  age,
+			Idle: idle,
+		})
+	}
+	return clients
+}
+
+// Monitor streams MONITOR output on the returned channel until ctx is
+// cancelled or maxLines lines have been delivered (0 means unbounded), then
+// closes the channel. go-redis v6's pooled *Client has no Monitor method,
+// so this dials its own connection and speaks MONITOR's RESP handshake
+// directly; it only supports a standalone server, not Sentinel/Cluster.
+func Monitor(server RedisServer, ctx context.Context, maxLines int) <-chan string {
+	lines := make(chan string)
+
+	go func() {
+		defer close(lines)
+
+		if len(server.ClusterAddrs) > 0 || len(server.SentinelAddrs) > 0 {
+			log.Println("monitor: only supported against a standalone server")
+			return
+		}
+
+		conn, err := net.Dial("tcp", server.Addr)
+		if err != nil {
+			log.Println("monitor dial error: ", err.Error())
+			return
+		}
+		defer conn.Close()
+
+		go func() {
+			<-ctx.Done()
+			conn.Close()
+		}()
+
+		reader := bufio.NewReader(conn)
+		if err := monitorHandshake(conn, reader, server); err != nil {
+			log.Println("monitor handshake error: ", err.Error())
+			return
+		}
+
+		count := 0
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			line = strings.TrimRight(line, "\r\n")
+			if strings.HasPrefix(line, "+") {
+				line = line[1:]
+			}
+			if line == "" {
+				continue
+			}
+
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+
+			count++
+			if maxLines > 0 && count >= maxLines {
+				return
+			}
+		}
+	}()
+
+	return lines
+}
+
+// monitorHandshake issues AUTH/SELECT/MONITOR as RESP arrays over conn and
+// reads past their +OK replies, leaving reader positioned at the start of
+// the MONITOR command stream.
+func monitorHandshake(conn net.Conn, reader *bufio.Reader, server RedisServer) error {
+	send := func(args ...string) error {
+		if _, err := fmt.Fprintf(conn, "*%d\r\n", len(args)); err != nil {
+			return err
+		}
+		for _, a := range args {
+			if _, err := fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(a), a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if server.Password != "" {
+		if err := send("AUTH", server.Password); err != nil {
+			return err
+		}
+		if _, err := reader.ReadString('\n'); err != nil {
+			return err
+		}
+	}
+
+	if server.DefaultDb != 0 {
+		if err := send("SELECT", strconv.Itoa(server.DefaultDb)); err != nil {
+			return err
+		}
+		if _, err := reader.ReadString('\n'); err != nil {
+			return err
+		}
+	}
+
+	if err := send("MONITOR"); err != nil {
+		return err
+	}
+	_, err := reader.ReadString('\n')
+	return err
+}
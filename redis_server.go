@@ -0,0 +1,19 @@
+package main
+
+import "crypto/tls"
+
+// RedisServer describes one connection target. newRedisClient picks the
+// right redis.UniversalClient implementation based on which fields are set:
+// ClusterAddrs for Redis Cluster, MasterName+SentinelAddrs for a
+// Sentinel-managed master, or Addr for a plain standalone server.
+type RedisServer struct {
+	Addr      string
+	Password  string
+	DefaultDb int
+
+	MasterName    string   // Sentinel master name; set together with SentinelAddrs
+	SentinelAddrs []string // Sentinel addresses, used when MasterName is set
+	ClusterAddrs  []string // shard addresses, set to connect to a Redis Cluster
+
+	TLSConfig *tls.Config
+}